@@ -0,0 +1,48 @@
+// Package exporter serves accounting.Stats as JSON and as Prometheus
+// metrics, so that long running rclone processes can be scraped by
+// monitoring systems instead of screen-scraping the log output.
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+)
+
+// Server serves a StatsInfo over HTTP
+type Server struct {
+	stats *accounting.StatsInfo
+	http  *http.Server
+}
+
+// NewServer creates a Server which will report on stats, listening on addr
+// when Serve is called
+func NewServer(stats *accounting.StatsInfo, addr string) *Server {
+	s := &Server{stats: stats}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.handleStats)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.http = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// Serve starts serving and blocks until the server exits
+func (s *Server) Serve() error {
+	return s.http.ListenAndServe()
+}
+
+// handleStats serves the current snapshot as JSON
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.stats.Snapshot()); err != nil {
+		fs.Errorf(nil, "exporter: failed to encode stats: %v", err)
+	}
+}
+
+// handleMetrics serves the current snapshot in Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeMetrics(w, s.stats.Snapshot())
+}
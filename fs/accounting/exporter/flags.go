@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/spf13/pflag"
+)
+
+// statsServerAddr holds the value of --stats-server-addr
+var statsServerAddr string
+
+// startOnce makes sure a second call to Start (e.g. from a command that
+// calls it defensively) doesn't spin up a second server
+var startOnce sync.Once
+
+func init() {
+	pflag.StringVar(&statsServerAddr, "stats-server-addr", "", "Serve stats as JSON (/stats) and Prometheus (/metrics) on this address, e.g. \":9445\"")
+}
+
+// Start launches the stats exporter HTTP server in the background if
+// --stats-server-addr was set; it is a no-op otherwise. It is safe to call
+// more than once.
+//
+// It is not called automatically on flag parsing: a command's Run should
+// call it explicitly once flags have been parsed and validated, the same
+// way other optional rclone subsystems (e.g. the rc server) are started
+// from the real command path rather than as a side effect of Set.
+func Start() {
+	if statsServerAddr == "" {
+		return
+	}
+	startOnce.Do(func() {
+		server := NewServer(accounting.Stats, statsServerAddr)
+		go func() {
+			if err := server.Serve(); err != nil {
+				fs.Errorf(nil, "stats server exited: %v", err)
+			}
+		}()
+	})
+}
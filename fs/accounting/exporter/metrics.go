@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ncw/rclone/fs/accounting"
+)
+
+// writeMetrics renders snap in Prometheus text exposition format
+func writeMetrics(w io.Writer, snap accounting.StatsSnapshot) {
+	writeCounter(w, "rclone_bytes_transferred_total", "Total bytes transferred", snap.Bytes)
+	writeCounter(w, "rclone_errors_total", "Total number of errors", snap.Errors)
+	writeCounter(w, "rclone_checks_total", "Total number of checks", snap.Checks)
+	writeCounter(w, "rclone_transfers_total", "Total number of transfers", snap.Transfers)
+	writeCounter(w, "rclone_deletes_total", "Total number of deletes", snap.Deletes)
+
+	_, _ = fmt.Fprintf(w, "# HELP rclone_queue_size_bytes Size of the queued work by kind\n")
+	_, _ = fmt.Fprintf(w, "# TYPE rclone_queue_size_bytes gauge\n")
+	_, _ = fmt.Fprintf(w, "rclone_queue_size_bytes{kind=\"check\"} %d\n", snap.CheckQueueSize)
+	_, _ = fmt.Fprintf(w, "rclone_queue_size_bytes{kind=\"transfer\"} %d\n", snap.TransferQueueSize)
+	_, _ = fmt.Fprintf(w, "rclone_queue_size_bytes{kind=\"rename\"} %d\n", snap.RenameQueueSize)
+
+	_, _ = fmt.Fprintf(w, "# HELP rclone_in_flight Number of operations currently in flight, by kind\n")
+	_, _ = fmt.Fprintf(w, "# TYPE rclone_in_flight gauge\n")
+	for _, kind := range sortedOpKeys(snap.Ops) {
+		_, _ = fmt.Fprintf(w, "rclone_in_flight{kind=%q} %d\n", kind, snap.Ops[kind].InFlight)
+	}
+
+	writeHistogram(w, "rclone_transfer_latency_seconds", "Transfer latency distribution", snap.TransferLatency)
+}
+
+func writeCounter(w io.Writer, name, help string, value int64) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	_, _ = fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	_, _ = fmt.Fprintf(w, "%s %d\n", name, value)
+}
+
+// writeHistogram renders snap as a standard Prometheus histogram (_bucket,
+// _sum, _count), using the latencyHistogram's fixed bucket boundaries as
+// the "le" values. Unlike a summary, these cumulative buckets stay
+// meaningfully aggregable (e.g. via histogram_quantile) across the many
+// long-running rclone processes this exporter is meant to serve.
+func writeHistogram(w io.Writer, name, help string, snap accounting.LatencySnapshot) {
+	if snap.Count == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	_, _ = fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for _, b := range snap.Buckets {
+		_, _ = fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, formatSeconds(b.UpperBound), b.Count)
+	}
+	_, _ = fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, snap.Count)
+	_, _ = fmt.Fprintf(w, "%s_sum %s\n", name, formatSeconds(snap.Sum))
+	_, _ = fmt.Fprintf(w, "%s_count %d\n", name, snap.Count)
+}
+
+// formatSeconds renders d in fractional seconds the way Prometheus expects
+// a histogram "le" or "_sum" value to be formatted
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// sortedOpKeys returns the keys of m in sorted order, so that output is
+// stable between scrapes
+func sortedOpKeys(m map[string]accounting.OpStatsSnapshot) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/fs/accounting"
+)
+
+func testSnapshot() accounting.StatsSnapshot {
+	s := accounting.NewStats()
+	s.BytesKind(accounting.OpUpload, 100)
+	s.TransferringKind(accounting.OpUpload, "a.txt")
+	s.DoneTransferringKind(accounting.OpUpload, "a.txt", true)
+	return s.Snapshot()
+}
+
+func TestWriteMetrics(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeMetrics(buf, testSnapshot())
+	out := buf.String()
+
+	for _, want := range []string{
+		"rclone_bytes_transferred_total 100",
+		"rclone_errors_total 0",
+		"rclone_checks_total 0",
+		"rclone_transfers_total 1",
+		"rclone_deletes_total 0",
+		`rclone_queue_size_bytes{kind="check"}`,
+		`rclone_queue_size_bytes{kind="transfer"}`,
+		`rclone_queue_size_bytes{kind="rename"}`,
+		`rclone_in_flight{kind="upload"}`,
+		"# TYPE rclone_transfer_latency_seconds histogram",
+		"rclone_transfer_latency_seconds_bucket{le=",
+		`rclone_transfer_latency_seconds_bucket{le="+Inf"} 1`,
+		"rclone_transfer_latency_seconds_sum",
+		"rclone_transfer_latency_seconds_count 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("writeMetrics output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMetricsSkipsEmptyHistogram(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeMetrics(buf, accounting.NewStats().Snapshot())
+	out := buf.String()
+
+	if strings.Contains(out, "rclone_transfer_latency_seconds") {
+		t.Errorf("writeMetrics should omit the histogram entirely when no samples were recorded, got:\n%s", out)
+	}
+}
+
+func TestSnapshotJSONRoundTrip(t *testing.T) {
+	snap := testSnapshot()
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(snap); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var got accounting.StatsSnapshot
+	if err := json.NewDecoder(buf).Decode(&got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if got.Bytes != snap.Bytes {
+		t.Errorf("Bytes = %d, want %d", got.Bytes, snap.Bytes)
+	}
+	if got.Transfers != snap.Transfers {
+		t.Errorf("Transfers = %d, want %d", got.Transfers, snap.Transfers)
+	}
+	if len(got.Ops) != len(snap.Ops) {
+		t.Errorf("Ops = %d entries, want %d", len(got.Ops), len(snap.Ops))
+	}
+}
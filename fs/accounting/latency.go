@@ -0,0 +1,229 @@
+package accounting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Latency buckets are logarithmically spaced between latencyMinBucket and
+// latencyMaxBucket so that a fixed, small number of buckets can cover
+// everything from a sub-millisecond API call to an hour-long stall, each
+// bucket being accurate to within roughly 20% of the true value - similar
+// to the approach used by HdrHistogram, but without the memory cost of
+// tracking every possible duration.
+const (
+	latencyMinBucket  = time.Microsecond
+	latencyMaxBucket  = time.Hour
+	latencyNumBuckets = 100
+)
+
+// latencyBucketBounds holds the upper bound of each bucket and is shared
+// read-only by every latencyHistogram.
+var latencyBucketBounds = newLatencyBucketBounds()
+
+func newLatencyBucketBounds() []time.Duration {
+	bounds := make([]time.Duration, latencyNumBuckets)
+	growth := math.Pow(float64(latencyMaxBucket)/float64(latencyMinBucket), 1.0/float64(latencyNumBuckets-1))
+	v := float64(latencyMinBucket)
+	for i := range bounds {
+		bounds[i] = time.Duration(v)
+		v *= growth
+	}
+	return bounds
+}
+
+// latencyHistogram is a bounded, lock-friendly histogram of durations used
+// to estimate percentiles without storing every sample.
+type latencyHistogram struct {
+	mu      sync.Mutex
+	buckets []int64
+	count   int64
+	sum     time.Duration
+	min     time.Duration
+	max     time.Duration
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{
+		buckets: make([]int64, len(latencyBucketBounds)),
+	}
+}
+
+// record adds d to the histogram
+func (h *latencyHistogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	idx := sort.Search(len(latencyBucketBounds), func(i int) bool {
+		return latencyBucketBounds[i] >= d
+	})
+	if idx >= len(latencyBucketBounds) {
+		idx = len(latencyBucketBounds) - 1
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buckets[idx]++
+	h.sum += d
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.count++
+}
+
+// reset clears all the samples recorded so far
+func (h *latencyHistogram) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.buckets {
+		h.buckets[i] = 0
+	}
+	h.count, h.sum, h.min, h.max = 0, 0, 0, 0
+}
+
+// percentileLocked returns the estimated value at percentile p (0..100);
+// the caller must hold h.mu
+func (h *latencyHistogram) percentileLocked(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(p / 100 * float64(h.count)))
+	if target < 1 {
+		target = 1
+	}
+	var cumulative int64
+	for i, n := range h.buckets {
+		cumulative += n
+		if cumulative >= target {
+			return latencyBucketBounds[i]
+		}
+	}
+	return h.max
+}
+
+// percentiles returns the estimated latency at each of the given
+// percentiles (0..100)
+func (h *latencyHistogram) percentiles(kinds ...float64) map[float64]time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[float64]time.Duration, len(kinds))
+	for _, p := range kinds {
+		out[p] = h.percentileLocked(p)
+	}
+	return out
+}
+
+// bucketCount is the cumulative number of samples at or below Bound
+type bucketCount struct {
+	Bound time.Duration
+	Count int64
+}
+
+// cumulativeBuckets returns, for every fixed bucket boundary, the number of
+// samples recorded at or below it - i.e. a Prometheus-style cumulative
+// histogram, which stays meaningfully aggregable across instances because
+// the boundaries are the same fixed set for every latencyHistogram.
+// Samples longer than the last boundary are counted in the last bucket.
+func (h *latencyHistogram) cumulativeBuckets() []bucketCount {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]bucketCount, len(latencyBucketBounds))
+	var cumulative int64
+	for i, n := range h.buckets {
+		cumulative += n
+		out[i] = bucketCount{Bound: latencyBucketBounds[i], Count: cumulative}
+	}
+	return out
+}
+
+// totalCount returns the total number of samples recorded
+func (h *latencyHistogram) totalCount() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// totalSum returns the sum of all durations recorded
+func (h *latencyHistogram) totalSum() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sum
+}
+
+// minDuration returns the smallest duration recorded
+func (h *latencyHistogram) minDuration() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.min
+}
+
+// maxDuration returns the largest duration recorded
+func (h *latencyHistogram) maxDuration() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.max
+}
+
+// mean returns the average duration recorded
+func (h *latencyHistogram) mean() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	return time.Duration(int64(h.sum) / h.count)
+}
+
+// String returns a compact "min/p50/p95/p99/max" summary
+func (h *latencyHistogram) String() string {
+	p := h.percentiles(50, 95, 99)
+	return fmt.Sprintf("%v/%v/%v/%v/%v", h.minDuration(), p[50], p[95], p[99], h.maxDuration())
+}
+
+// startTimes tracks the time at which an in-progress remote started, so
+// that Done* calls can compute how long it took
+type startTimes struct {
+	mu    sync.Mutex
+	start map[string]time.Time
+}
+
+func newStartTimes() *startTimes {
+	return &startTimes{start: make(map[string]time.Time)}
+}
+
+// set records that remote started now
+func (t *startTimes) set(remote string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start[remote] = time.Now()
+}
+
+// since returns the time elapsed since remote was started, and removes it
+// from the tracker; ok is false if remote was never started
+func (t *startTimes) since(remote string) (d time.Duration, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.start[remote]
+	if !ok {
+		return 0, false
+	}
+	delete(t.start, remote)
+	return time.Since(start), true
+}
+
+// list returns the remotes currently being tracked together with the time
+// they started, for use in a StatsSnapshot
+func (t *startTimes) list() []InProgressTransfer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]InProgressTransfer, 0, len(t.start))
+	for remote, start := range t.start {
+		out = append(out, InProgressTransfer{Remote: remote, Started: start})
+	}
+	return out
+}
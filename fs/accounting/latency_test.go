@@ -0,0 +1,122 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogramEmpty(t *testing.T) {
+	h := newLatencyHistogram()
+	if got := h.minDuration(); got != 0 {
+		t.Errorf("minDuration() on empty histogram = %v, want 0", got)
+	}
+	if got := h.maxDuration(); got != 0 {
+		t.Errorf("maxDuration() on empty histogram = %v, want 0", got)
+	}
+	if got := h.mean(); got != 0 {
+		t.Errorf("mean() on empty histogram = %v, want 0", got)
+	}
+	if got := h.percentileLocked(50); got != 0 {
+		t.Errorf("percentileLocked(50) on empty histogram = %v, want 0", got)
+	}
+}
+
+func TestLatencyHistogramMinMaxMean(t *testing.T) {
+	h := newLatencyHistogram()
+	samples := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+	}
+	for _, d := range samples {
+		h.record(d)
+	}
+	if got := h.minDuration(); got > 10*time.Millisecond*6/5 || got < 10*time.Millisecond*4/5 {
+		t.Errorf("minDuration() = %v, want ~10ms", got)
+	}
+	if got := h.maxDuration(); got > 30*time.Millisecond*6/5 || got < 30*time.Millisecond*4/5 {
+		t.Errorf("maxDuration() = %v, want ~30ms", got)
+	}
+	mean := h.mean()
+	if mean <= 0 {
+		t.Errorf("mean() = %v, want > 0", mean)
+	}
+}
+
+func TestLatencyHistogramPercentileRank(t *testing.T) {
+	h := newLatencyHistogram()
+	// 100 samples evenly spread from 1ms to 100ms: p50 should land well
+	// below p99, and p100 should be close to the max recorded value
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	p50 := h.percentileLocked(50)
+	p99 := h.percentileLocked(99)
+	pMax := h.percentileLocked(100)
+	if p50 >= p99 {
+		t.Errorf("p50 (%v) should be less than p99 (%v)", p50, p99)
+	}
+	if pMax < 90*time.Millisecond {
+		t.Errorf("p100 = %v, want close to the 100ms max", pMax)
+	}
+}
+
+func TestLatencyHistogramBucketSearchBoundary(t *testing.T) {
+	h := newLatencyHistogram()
+	// exact bucket boundary values must not be misclassified into the
+	// bucket below by the sort.Search comparison
+	for _, b := range []time.Duration{latencyBucketBounds[0], latencyBucketBounds[len(latencyBucketBounds)/2], latencyMaxBucket * 10} {
+		h.record(b)
+	}
+	if h.count != 3 {
+		t.Fatalf("count = %d, want 3", h.count)
+	}
+	for _, i := range []int{0, len(latencyBucketBounds) / 2, len(latencyBucketBounds) - 1} {
+		if h.buckets[i] != 1 {
+			t.Errorf("buckets[%d] = %d, want 1", i, h.buckets[i])
+		}
+	}
+	// a duration far beyond the top bucket must clamp into the last
+	// bucket rather than being dropped or indexing out of range
+	if got := h.maxDuration(); got != latencyMaxBucket*10 {
+		t.Errorf("maxDuration() = %v, want %v", got, latencyMaxBucket*10)
+	}
+}
+
+func TestLatencyHistogramNegativeDuration(t *testing.T) {
+	h := newLatencyHistogram()
+	h.record(-5 * time.Second)
+	if got := h.minDuration(); got != 0 {
+		t.Errorf("minDuration() after negative record = %v, want 0 (clamped)", got)
+	}
+}
+
+func TestLatencyHistogramReset(t *testing.T) {
+	h := newLatencyHistogram()
+	h.record(time.Second)
+	h.reset()
+	if h.count != 0 || h.sum != 0 || h.min != 0 || h.max != 0 {
+		t.Errorf("reset() left non-zero state: %+v", h)
+	}
+	for _, n := range h.buckets {
+		if n != 0 {
+			t.Fatalf("reset() left a non-zero bucket count: %v", h.buckets)
+		}
+	}
+}
+
+func TestPercentileKey(t *testing.T) {
+	for _, test := range []struct {
+		p    float64
+		want string
+	}{
+		{50, "p50"},
+		{95, "p95"},
+		{99, "p99"},
+		{99.9, "p99.9"},
+	} {
+		if got := percentileKey(test.p); got != test.want {
+			t.Errorf("percentileKey(%v) = %q, want %q", test.p, got, test.want)
+		}
+	}
+}
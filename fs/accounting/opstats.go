@@ -0,0 +1,197 @@
+package accounting
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// OpKind identifies the class of operation that a set of stats belongs to,
+// so that, for example, a slow upload can be told apart from a slow
+// download during a bidirectional copy.
+type OpKind int
+
+// The kinds of operation tracked independently by StatsInfo. OpUnknown
+// collects activity reported through the legacy, kind-less methods (Bytes,
+// Transferring, DoneTransferring) so that callers which haven't yet been
+// migrated to the Kind-aware variants still show up in the breakdown.
+//
+// Checking and DoneChecking* always report under OpCheck, and Deletes and
+// DeleteError under OpDelete. OpUpload, OpDownload and OpRename are only
+// reported through BytesKind/TransferringKind/DoneTransferringKind;
+// transfers made through the kind-less methods are counted under
+// OpUnknown.
+const (
+	OpUpload OpKind = iota
+	OpDownload
+	OpDelete
+	OpCheck
+	OpRename
+	OpUnknown
+	numOpKinds
+)
+
+// String returns a human readable name for kind
+func (k OpKind) String() string {
+	switch k {
+	case OpUpload:
+		return "upload"
+	case OpDownload:
+		return "download"
+	case OpDelete:
+		return "delete"
+	case OpCheck:
+		return "check"
+	case OpRename:
+		return "rename"
+	case OpUnknown:
+		return "unknown"
+	}
+	return "unknown"
+}
+
+// OpStatsSnapshot is a point in time, plain data copy of a perOpStats,
+// suitable for display or serialisation
+type OpStatsSnapshot struct {
+	Kind     OpKind
+	Bytes    int64
+	Count    int64
+	Errors   int64
+	InFlight int64
+	Rate10s  float64 // bytes/s, EWMA over the last 10s
+	Rate60s  float64 // bytes/s, EWMA over the last 60s
+	Rate5m   float64 // bytes/s, EWMA over the last 5m
+}
+
+// rateWindow is an exponentially weighted moving average of a byte rate,
+// decaying with the given time constant
+type rateWindow struct {
+	mu       sync.Mutex
+	tau      float64 // time constant in seconds
+	rate     float64 // bytes/s
+	lastTime time.Time
+}
+
+func newRateWindow(window time.Duration) *rateWindow {
+	return &rateWindow{
+		tau:      window.Seconds(),
+		lastTime: time.Now(),
+	}
+}
+
+// add folds n bytes just transferred into the moving average
+func (r *rateWindow) add(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	dt := now.Sub(r.lastTime).Seconds()
+	r.lastTime = now
+	if dt <= 0 {
+		dt = 1e-9
+	}
+	instant := float64(n) / dt
+	alpha := 1 - math.Exp(-dt/r.tau)
+	r.rate += alpha * (instant - r.rate)
+}
+
+// value returns the current estimated rate in bytes/s
+func (r *rateWindow) value() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rate
+}
+
+// perOpStats holds the bytes, ops, errors and in-flight remotes for a
+// single OpKind, each with their own sliding-window rate estimators
+type perOpStats struct {
+	mu       sync.Mutex
+	bytes    int64
+	count    int64
+	errors   int64
+	inFlight *stringSet
+	inCount  int64
+	rate10s  *rateWindow
+	rate60s  *rateWindow
+	rate5m   *rateWindow
+}
+
+func newPerOpStats() *perOpStats {
+	return &perOpStats{
+		inFlight: newStringSet(0),
+		rate10s:  newRateWindow(10 * time.Second),
+		rate60s:  newRateWindow(60 * time.Second),
+		rate5m:   newRateWindow(5 * time.Minute),
+	}
+}
+
+// addBytes accounts n bytes transferred by this op kind and feeds the rate
+// estimators
+func (p *perOpStats) addBytes(n int64) {
+	p.mu.Lock()
+	p.bytes += n
+	p.mu.Unlock()
+	p.rate10s.add(n)
+	p.rate60s.add(n)
+	p.rate5m.add(n)
+}
+
+// addCount accounts n completed operations of this kind directly, for
+// operations with no begin/done pair of their own (e.g. deletes)
+func (p *perOpStats) addCount(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count += n
+}
+
+// addError accounts a single failed operation of this kind, for operations
+// with no begin/done pair of their own (e.g. deletes)
+func (p *perOpStats) addError() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errors++
+}
+
+// begin marks remote as in-flight for this op kind
+func (p *perOpStats) begin(remote string) {
+	p.inFlight.add(remote)
+	p.mu.Lock()
+	p.inCount++
+	p.mu.Unlock()
+}
+
+// done marks remote as no longer in-flight for this op kind and accounts
+// one completed operation, incrementing errors if ok is false
+func (p *perOpStats) done(remote string, ok bool) {
+	p.inFlight.del(remote)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inCount--
+	p.count++
+	if !ok {
+		p.errors++
+	}
+}
+
+// reset zeros the counters for this op kind; rates are left to decay
+// naturally since they reflect real elapsed wall clock time
+func (p *perOpStats) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes, p.count, p.errors = 0, 0, 0
+}
+
+// snapshot takes a point in time copy of this op kind's stats
+func (p *perOpStats) snapshot(kind OpKind) OpStatsSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return OpStatsSnapshot{
+		Kind:     kind,
+		Bytes:    p.bytes,
+		Count:    p.count,
+		Errors:   p.errors,
+		InFlight: p.inCount,
+		Rate10s:  p.rate10s.value(),
+		Rate60s:  p.rate60s.value(),
+		Rate5m:   p.rate5m.value(),
+	}
+}
@@ -0,0 +1,115 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpKindString(t *testing.T) {
+	for _, test := range []struct {
+		kind OpKind
+		want string
+	}{
+		{OpUpload, "upload"},
+		{OpDownload, "download"},
+		{OpDelete, "delete"},
+		{OpCheck, "check"},
+		{OpRename, "rename"},
+		{OpUnknown, "unknown"},
+		{numOpKinds, "unknown"},
+		{OpKind(-1), "unknown"},
+	} {
+		if got := test.kind.String(); got != test.want {
+			t.Errorf("OpKind(%d).String() = %q, want %q", test.kind, got, test.want)
+		}
+	}
+}
+
+func TestPerOpStatsBytesCountErrors(t *testing.T) {
+	p := newPerOpStats()
+	p.addBytes(100)
+	p.addBytes(50)
+	p.addCount(2)
+	p.addError()
+
+	snap := p.snapshot(OpUpload)
+	if snap.Bytes != 150 {
+		t.Errorf("Bytes = %d, want 150", snap.Bytes)
+	}
+	if snap.Count != 2 {
+		t.Errorf("Count = %d, want 2", snap.Count)
+	}
+	if snap.Errors != 1 {
+		t.Errorf("Errors = %d, want 1", snap.Errors)
+	}
+}
+
+func TestPerOpStatsBeginDone(t *testing.T) {
+	p := newPerOpStats()
+	p.begin("a")
+	p.begin("b")
+	if snap := p.snapshot(OpCheck); snap.InFlight != 2 {
+		t.Fatalf("InFlight = %d, want 2", snap.InFlight)
+	}
+
+	p.done("a", true)
+	snap := p.snapshot(OpCheck)
+	if snap.InFlight != 1 {
+		t.Errorf("InFlight after one done = %d, want 1", snap.InFlight)
+	}
+	if snap.Count != 1 {
+		t.Errorf("Count after one done = %d, want 1", snap.Count)
+	}
+	if snap.Errors != 0 {
+		t.Errorf("Errors after successful done = %d, want 0", snap.Errors)
+	}
+
+	p.done("b", false)
+	snap = p.snapshot(OpCheck)
+	if snap.InFlight != 0 {
+		t.Errorf("InFlight after both done = %d, want 0", snap.InFlight)
+	}
+	if snap.Count != 2 {
+		t.Errorf("Count after both done = %d, want 2", snap.Count)
+	}
+	if snap.Errors != 1 {
+		t.Errorf("Errors after one failure = %d, want 1", snap.Errors)
+	}
+}
+
+func TestPerOpStatsReset(t *testing.T) {
+	p := newPerOpStats()
+	p.addBytes(100)
+	p.addCount(3)
+	p.addError()
+	p.reset()
+
+	snap := p.snapshot(OpUpload)
+	if snap.Bytes != 0 || snap.Count != 0 || snap.Errors != 0 {
+		t.Errorf("snapshot after reset = %+v, want all zero", snap)
+	}
+}
+
+func TestRateWindowZeroElapsed(t *testing.T) {
+	// two adds in immediate succession must not divide by zero or panic
+	r := newRateWindow(10 * time.Second)
+	r.add(1024)
+	r.add(1024)
+	if r.value() < 0 {
+		t.Errorf("value() = %v, want >= 0", r.value())
+	}
+}
+
+func TestRateWindowConverges(t *testing.T) {
+	r := newRateWindow(1 * time.Millisecond)
+	for i := 0; i < 50; i++ {
+		r.add(1000)
+		time.Sleep(2 * time.Millisecond)
+	}
+	// with a 1ms time constant and steady 1000 bytes every 2ms, the
+	// estimate should settle near 500,000 bytes/s and not still be at its
+	// zero-value starting point
+	if r.value() <= 0 {
+		t.Errorf("value() = %v, want > 0 after sustained traffic", r.value())
+	}
+}
@@ -0,0 +1,129 @@
+package accounting
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// InProgressTransfer describes a single remote that is currently being
+// transferred or checked, and when it started
+type InProgressTransfer struct {
+	Remote  string
+	Started time.Time
+}
+
+// LatencyBucket is the cumulative count of samples at or below UpperBound,
+// using the same fixed boundaries for every latencyHistogram so that
+// buckets from different rclone processes can be aggregated. The last
+// bucket also counts samples longer than its UpperBound.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      int64
+}
+
+// LatencySnapshot is a plain data copy of a latencyHistogram
+type LatencySnapshot struct {
+	Min         time.Duration
+	Max         time.Duration
+	Mean        time.Duration
+	Count       int64
+	Sum         time.Duration
+	Percentiles map[string]time.Duration // keyed "p50", "p95", "p99", ...
+	Buckets     []LatencyBucket
+}
+
+// StatsSnapshot is a fully-populated, plain data copy of a StatsInfo,
+// suitable for encoding as JSON or rendering as Prometheus metrics
+type StatsSnapshot struct {
+	Bytes             int64
+	Errors            int64
+	Checks            int64
+	Transfers         int64
+	Deletes           int64
+	CheckQueue        int
+	CheckQueueSize    int64
+	TransferQueue     int
+	TransferQueueSize int64
+	RenameQueue       int
+	RenameQueueSize   int64
+	Elapsed           time.Duration
+	Speed             float64 // bytes/s
+	ETA               time.Duration
+	Checking          []InProgressTransfer
+	Transferring      []InProgressTransfer
+	Ops               map[string]OpStatsSnapshot // keyed by OpKind.String()
+	TransferLatency   LatencySnapshot
+	CheckLatency      LatencySnapshot
+}
+
+// snapshotLatency takes a point in time copy of h
+func snapshotLatency(h *latencyHistogram) LatencySnapshot {
+	percentiles := h.percentiles(50, 95, 99)
+	cumulative := h.cumulativeBuckets()
+	snap := LatencySnapshot{
+		Min:         h.minDuration(),
+		Max:         h.maxDuration(),
+		Mean:        h.mean(),
+		Count:       h.totalCount(),
+		Sum:         h.totalSum(),
+		Percentiles: make(map[string]time.Duration, len(percentiles)),
+		Buckets:     make([]LatencyBucket, len(cumulative)),
+	}
+	for p, d := range percentiles {
+		snap.Percentiles[percentileKey(p)] = d
+	}
+	for i, b := range cumulative {
+		snap.Buckets[i] = LatencyBucket{UpperBound: b.Bound, Count: b.Count}
+	}
+	return snap
+}
+
+// percentileKey renders p (0..100) as a map key such as "p50" or "p99.9"
+func percentileKey(p float64) string {
+	if p == math.Trunc(p) {
+		return fmt.Sprintf("p%d", int(p))
+	}
+	return fmt.Sprintf("p%g", p)
+}
+
+// Snapshot takes a point in time copy of the stats suitable for programmatic
+// consumers such as the accounting/exporter package
+func (s *StatsInfo) Snapshot() StatsSnapshot {
+	s.mu.RLock()
+	snap := StatsSnapshot{
+		Bytes:             s.bytes,
+		Errors:            s.errors,
+		Checks:            s.checks,
+		Transfers:         s.transfers,
+		Deletes:           s.deletes,
+		CheckQueue:        s.checkQueue,
+		CheckQueueSize:    s.checkQueueSize,
+		TransferQueue:     s.transferQueue,
+		TransferQueueSize: s.transferQueueSize,
+		RenameQueue:       s.renameQueue,
+		RenameQueueSize:   s.renameQueueSize,
+		Elapsed:           time.Since(s.start),
+	}
+	s.mu.RUnlock()
+
+	if snap.Elapsed > 0 {
+		snap.Speed = float64(snap.Bytes) / snap.Elapsed.Seconds()
+	}
+	if snap.Speed > 0 {
+		snap.ETA = time.Duration(float64(snap.TransferQueueSize) / snap.Speed * float64(time.Second))
+	}
+
+	snap.Checking = s.checkStartTime.list()
+	snap.Transferring = s.transferStartTime.list()
+
+	snap.Ops = make(map[string]OpStatsSnapshot, numOpKinds)
+	for kind := OpKind(0); kind < numOpKinds; kind++ {
+		snap.Ops[kind.String()] = s.ops[kind].snapshot(kind)
+	}
+
+	snap.TransferLatency = snapshotLatency(s.transferLatency)
+	snap.CheckLatency = snapshotLatency(s.checkLatency)
+
+	return snap
+}
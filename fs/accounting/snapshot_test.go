@@ -0,0 +1,50 @@
+package accounting
+
+import "testing"
+
+func TestStatsInfoSnapshot(t *testing.T) {
+	s := NewStats()
+
+	s.BytesKind(OpUpload, 100)
+	s.TransferringKind(OpUpload, "a.txt")
+	s.DoneTransferringKind(OpUpload, "a.txt", true)
+
+	s.Checking("b.txt")
+	s.DoneCheckingWithError("b.txt", nil)
+
+	s.TransferringKind(OpDownload, "c.txt")
+
+	snap := s.Snapshot()
+
+	if snap.Bytes != 100 {
+		t.Errorf("Bytes = %d, want 100", snap.Bytes)
+	}
+	if snap.Transfers != 1 {
+		t.Errorf("Transfers = %d, want 1", snap.Transfers)
+	}
+	if snap.Checks != 1 {
+		t.Errorf("Checks = %d, want 1", snap.Checks)
+	}
+
+	upload, ok := snap.Ops[OpUpload.String()]
+	if !ok {
+		t.Fatalf("Ops missing %q key", OpUpload.String())
+	}
+	if upload.Bytes != 100 || upload.Count != 1 {
+		t.Errorf("upload ops = %+v, want Bytes=100 Count=1", upload)
+	}
+
+	if len(snap.Transferring) != 1 || snap.Transferring[0].Remote != "c.txt" {
+		t.Errorf("Transferring = %+v, want one in-progress transfer for c.txt", snap.Transferring)
+	}
+	if len(snap.Checking) != 0 {
+		t.Errorf("Checking = %+v, want none in progress", snap.Checking)
+	}
+
+	if snap.TransferLatency.Count != 1 {
+		t.Errorf("TransferLatency.Count = %d, want 1", snap.TransferLatency.Count)
+	}
+	if snap.CheckLatency.Count != 1 {
+		t.Errorf("CheckLatency.Count = %d, want 1", snap.CheckLatency.Count)
+	}
+}
@@ -39,16 +39,29 @@ type StatsInfo struct {
 	deletes           int64
 	start             time.Time
 	inProgress        *inProgress
+	transferStartTime *startTimes
+	transferLatency   *latencyHistogram
+	checkStartTime    *startTimes
+	checkLatency      *latencyHistogram
+	ops               [numOpKinds]*perOpStats
 }
 
 // NewStats cretates an initialised StatsInfo
 func NewStats() *StatsInfo {
-	return &StatsInfo{
-		checking:     newStringSet(fs.Config.Checkers),
-		transferring: newStringSet(fs.Config.Transfers),
-		start:        time.Now(),
-		inProgress:   newInProgress(),
+	s := &StatsInfo{
+		checking:          newStringSet(fs.Config.Checkers),
+		transferring:      newStringSet(fs.Config.Transfers),
+		start:             time.Now(),
+		inProgress:        newInProgress(),
+		transferStartTime: newStartTimes(),
+		transferLatency:   newLatencyHistogram(),
+		checkStartTime:    newStartTimes(),
+		checkLatency:      newLatencyHistogram(),
 	}
+	for kind := range s.ops {
+		s.ops[kind] = newPerOpStats()
+	}
+	return s
 }
 
 // String convert the StatsInfo to a string for printing
@@ -103,17 +116,38 @@ Errors:        %10d
 Checks:        %10d / %d, %d%%
 Transferred:   %10d / %d, %d%%
 Elapsed time:  %10v
+Transfer latency: %10s (min/p50/p95/p99/max)
+Check latency:    %10s (min/p50/p95/p99/max)
 `,
 		fs.SizeSuffix(s.bytes), fs.SizeSuffix(totalSize).Unit("Bytes"), percent(s.bytes, totalSize), fs.SizeSuffix(speed).Unit(strings.Title(fs.Config.DataRateUnit)+"/s"), etaString, xfrchkString,
 		s.errors,
 		s.checks, totalChecks, percent(s.checks, totalChecks),
 		s.transfers, totalTransfer, percent(s.transfers, totalTransfer),
-		dtRounded)
+		dtRounded,
+		s.transferLatency,
+		s.checkLatency)
 
 	// checking and transferring have their own locking so unlock
 	// here to prevent deadlock on GetBytes
 	s.mu.RUnlock()
 
+	_, _ = fmt.Fprintf(buf, "Breakdown:\n")
+	for kind := OpKind(0); kind < numOpKinds; kind++ {
+		snap := s.ops[kind].snapshot(kind)
+		if snap.Bytes == 0 && snap.Count == 0 && snap.InFlight == 0 {
+			continue
+		}
+		avg := 0.0
+		if dtSeconds > 0 {
+			avg = float64(snap.Bytes) / dtSeconds
+		}
+		_, _ = fmt.Fprintf(buf, "  %-9s %10s, %d ops, %d errors, %d in flight, %s/s now, %s/s avg\n",
+			kind.String()+":",
+			fs.SizeSuffix(snap.Bytes).Unit("Bytes"),
+			snap.Count, snap.Errors, snap.InFlight,
+			fs.SizeSuffix(snap.Rate10s), fs.SizeSuffix(avg))
+	}
+
 	if !s.checking.empty() {
 		_, _ = fmt.Fprintf(buf, "Checking:\n%s\n", s.checking)
 	}
@@ -129,10 +163,26 @@ func (s *StatsInfo) Log() {
 }
 
 // Bytes updates the stats for bytes bytes
+//
+// Callers which know what kind of operation (upload, download, ...) the
+// bytes belong to should use BytesKind instead so they show up in the
+// per-class breakdown.
 func (s *StatsInfo) Bytes(bytes int64) {
+	s.BytesKind(OpUnknown, bytes)
+}
+
+// BytesKind updates the stats for bytes bytes of the given kind of operation
+func (s *StatsInfo) BytesKind(kind OpKind, bytes int64) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.bytes += bytes
+	s.mu.Unlock()
+	s.ops[kind].addBytes(bytes)
+}
+
+// GetOpStats returns a snapshot of the stats for the given kind of
+// operation, for programmatic consumers
+func (s *StatsInfo) GetOpStats(kind OpKind) OpStatsSnapshot {
+	return s.ops[kind].snapshot(kind)
 }
 
 // GetBytes returns the number of bytes transferred so far
@@ -166,9 +216,19 @@ func (s *StatsInfo) GetLastError() error {
 // Deletes updates the stats for deletes
 func (s *StatsInfo) Deletes(deletes int64) int64 {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.deletes += deletes
-	return s.deletes
+	n := s.deletes
+	s.mu.Unlock()
+	s.ops[OpDelete].addCount(deletes)
+	return n
+}
+
+// DeleteError records that a delete failed with err, so that it is counted
+// both in the global error total and in the per-class breakdown for
+// OpDelete
+func (s *StatsInfo) DeleteError(err error) {
+	s.ops[OpDelete].addError()
+	s.Error(err)
 }
 
 // ResetCounters sets the counters (bytes, checks, errors, transfers) to 0
@@ -180,6 +240,11 @@ func (s *StatsInfo) ResetCounters() {
 	s.checks = 0
 	s.transfers = 0
 	s.deletes = 0
+	s.transferLatency.reset()
+	s.checkLatency.reset()
+	for _, op := range s.ops {
+		op.reset()
+	}
 }
 
 // ResetErrors sets the errors count to 0
@@ -207,14 +272,38 @@ func (s *StatsInfo) Error(err error) {
 // Checking adds a check into the stats
 func (s *StatsInfo) Checking(remote string) {
 	s.checking.add(remote)
+	s.checkStartTime.set(remote)
+	s.ops[OpCheck].begin(remote)
 }
 
-// DoneChecking removes a check from the stats
+// DoneChecking removes a check from the stats, assuming it succeeded
+//
+// Callers that know whether the check succeeded should use
+// DoneCheckingWithError instead so failures show up in the per-class
+// breakdown.
 func (s *StatsInfo) DoneChecking(remote string) {
+	s.DoneCheckingWithError(remote, nil)
+}
+
+// DoneCheckingWithError removes a check from the stats; if err is non-nil
+// the check is counted as failed, both globally and for OpCheck
+func (s *StatsInfo) DoneCheckingWithError(remote string, err error) {
 	s.checking.del(remote)
+	if d, ok := s.checkStartTime.since(remote); ok {
+		s.RecordCheckLatency(remote, d)
+	}
+	s.ops[OpCheck].done(remote, err == nil)
 	s.mu.Lock()
 	s.checks++
 	s.mu.Unlock()
+	if err != nil {
+		s.Error(err)
+	}
+}
+
+// RecordCheckLatency records d as the time a check of remote took
+func (s *StatsInfo) RecordCheckLatency(remote string, d time.Duration) {
+	s.checkLatency.record(d)
 }
 
 // GetTransfers reads the number of transfers
@@ -225,15 +314,40 @@ func (s *StatsInfo) GetTransfers() int64 {
 }
 
 // Transferring adds a transfer into the stats
+//
+// Callers which know what kind of transfer (OpUpload, OpDownload,
+// OpRename) this is should use TransferringKind instead so it shows up in
+// the per-class breakdown.
 func (s *StatsInfo) Transferring(remote string) {
+	s.TransferringKind(OpUnknown, remote)
+}
+
+// TransferringKind adds a transfer of the given kind into the stats
+func (s *StatsInfo) TransferringKind(kind OpKind, remote string) {
 	s.transferring.add(remote)
+	s.transferStartTime.set(remote)
+	s.ops[kind].begin(remote)
 }
 
 // DoneTransferring removes a transfer from the stats
 //
 // if ok is true then it increments the transfers count
+//
+// Callers which know what kind of transfer this is should use
+// DoneTransferringKind instead so it shows up in the per-class breakdown.
 func (s *StatsInfo) DoneTransferring(remote string, ok bool) {
+	s.DoneTransferringKind(OpUnknown, remote, ok)
+}
+
+// DoneTransferringKind removes a transfer of the given kind from the stats
+//
+// if ok is true then it increments the transfers count
+func (s *StatsInfo) DoneTransferringKind(kind OpKind, remote string, ok bool) {
 	s.transferring.del(remote)
+	if d, found := s.transferStartTime.since(remote); found {
+		s.RecordTransferLatency(remote, d)
+	}
+	s.ops[kind].done(remote, ok)
 	if ok {
 		s.mu.Lock()
 		s.transfers++
@@ -241,6 +355,53 @@ func (s *StatsInfo) DoneTransferring(remote string, ok bool) {
 	}
 }
 
+// RecordTransferLatency records d as the time a transfer of remote took
+func (s *StatsInfo) RecordTransferLatency(remote string, d time.Duration) {
+	s.transferLatency.record(d)
+}
+
+// Percentiles returns the estimated transfer latency at each of the given
+// percentiles (0..100)
+func (s *StatsInfo) Percentiles(kinds ...float64) map[float64]time.Duration {
+	return s.transferLatency.percentiles(kinds...)
+}
+
+// Min returns the smallest transfer latency seen so far
+func (s *StatsInfo) Min() time.Duration {
+	return s.transferLatency.minDuration()
+}
+
+// Max returns the largest transfer latency seen so far
+func (s *StatsInfo) Max() time.Duration {
+	return s.transferLatency.maxDuration()
+}
+
+// Mean returns the average transfer latency seen so far
+func (s *StatsInfo) Mean() time.Duration {
+	return s.transferLatency.mean()
+}
+
+// CheckPercentiles returns the estimated check latency at each of the
+// given percentiles (0..100)
+func (s *StatsInfo) CheckPercentiles(kinds ...float64) map[float64]time.Duration {
+	return s.checkLatency.percentiles(kinds...)
+}
+
+// CheckMin returns the smallest check latency seen so far
+func (s *StatsInfo) CheckMin() time.Duration {
+	return s.checkLatency.minDuration()
+}
+
+// CheckMax returns the largest check latency seen so far
+func (s *StatsInfo) CheckMax() time.Duration {
+	return s.checkLatency.maxDuration()
+}
+
+// CheckMean returns the average check latency seen so far
+func (s *StatsInfo) CheckMean() time.Duration {
+	return s.checkLatency.mean()
+}
+
 // SetCheckQueue sets the number of queued checks
 func (s *StatsInfo) SetCheckQueue(n int, size int64) {
 	s.mu.Lock()